@@ -0,0 +1,103 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	"github.com/tbogdala/groggy"
+)
+
+// GetRenderableAsync mirrors GetRenderable but is safe to call off the GL
+// thread: mesh data (gombz or SrcFile) is parsed on a worker goroutine, and
+// the rest of the work is queued onto gq to be drained on the GL thread, in
+// the spirit of glop's render.Queue. Texture files are not parsed on the
+// worker: they are loaded by GetRenderable inside that queued work, on the
+// GL thread, together with the VBO and texture uploads. The
+// returned channel receives the finished Renderable exactly once and is
+// then closed.
+//
+// Every ComponentMesh belonging to c must have its SrcMesh loadable via
+// LoadSrcMesh (i.e. BinFile or SrcFile set) before calling this, since that
+// parsing step is what runs off the GL thread.
+func (c *Component) GetRenderableAsync(gq *fizzle.GLQueue, tm *fizzle.TextureManager, shaders map[string]*fizzle.RenderShader) <-chan *fizzle.Renderable {
+	result := make(chan *fizzle.Renderable, 1)
+
+	go func() {
+		// serialize against any other in-flight (or concurrent) load of this
+		// same Component, so two callers never race on cachedRenderable,
+		// SrcMesh or renderable. The lock is held until the GL-thread work
+		// below actually finishes, not just until it's queued, so a second
+		// caller blocked on it is guaranteed to see the finished
+		// cachedRenderable rather than kick off a redundant load of its own.
+		c.renderMu.Lock()
+
+		if c.cachedRenderable != nil {
+			cached := c.cachedRenderable
+			c.renderMu.Unlock()
+			gq.Queue(func() {
+				result <- cached
+				close(result)
+			})
+			return
+		}
+
+		for _, compMesh := range c.Meshes {
+			if compMesh.SrcMesh != nil {
+				continue
+			}
+			if err := compMesh.LoadSrcMesh(); err != nil {
+				groggy.Log("ERROR", "GetRenderableAsync failed to load mesh data for %s: %v", c.Name, err)
+			}
+		}
+
+		gq.Queue(func() {
+			defer c.renderMu.Unlock()
+			result <- c.GetRenderable(tm, shaders)
+			close(result)
+		})
+	}()
+
+	return result
+}
+
+// SetVertices replaces the mesh's vertex positions and re-uploads its VBO,
+// for callers that procedurally modify mesh geometry at runtime (morphing,
+// destructible geometry, per-instance tinting via a cloned mesh). If gq is
+// non-nil the GPU upload is queued through it so this can be called from a
+// worker goroutine; otherwise the upload happens inline, so this must only
+// be called from the GL thread in that case.
+func (cm *ComponentMesh) SetVertices(gq *fizzle.GLQueue, vertices []mgl.Vec3) error {
+	if cm.SrcMesh == nil {
+		return fmt.Errorf("component: no internal data present for component mesh to set vertices on")
+	}
+	if len(vertices) != len(cm.SrcMesh.Vertices) {
+		return fmt.Errorf("component: SetVertices got %d vertices, expected %d", len(vertices), len(cm.SrcMesh.Vertices))
+	}
+
+	cm.SrcMesh.Vertices = vertices
+
+	upload := func() {
+		if cm.renderable != nil {
+			cm.renderable.Core.UpdateVertices(vertices)
+		}
+	}
+
+	if gq != nil {
+		gq.Queue(upload)
+	} else {
+		upload()
+	}
+
+	return nil
+}
+
+// Note: tm.GetTexture itself still performs glGenTextures/glTexImage2D
+// inline. Making that queue through gq as well requires the change to live
+// in fizzle.TextureManager (outside this package); GetRenderableAsync above
+// already routes its own GL uploads through gq so that, once TextureManager
+// is updated upstream, a fully GL-thread-safe load only needs this call
+// wrapped in its own goroutine plus gq.Queue the way it's done here.
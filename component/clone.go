@@ -0,0 +1,70 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/gombz"
+)
+
+// DeepClone makes a new component like Clone, except that each mesh's
+// vertex/index data is duplicated so that the clone's Renderable (once
+// built by GetRenderable) gets its own VBOs instead of aliasing the
+// source's. This is for callers that need to mutate per-instance vertex
+// data -- morphing, destructible geometry, per-instance tinting via
+// SetVertices -- without that mutation showing up on every other clone.
+func (c *Component) DeepClone() *Component {
+	clone := new(Component)
+	clone.Name = c.Name
+	clone.Location = c.Location
+	clone.ChildReferences = c.ChildReferences
+	clone.Collisions = c.Collisions
+	clone.Properties = c.Properties
+	clone.Material = c.Material
+	clone.componentDirPath = c.componentDirPath
+	clone.vfsSource = c.vfsSource
+	clone.vfsDir = c.vfsDir
+
+	clone.Meshes = make([]*ComponentMesh, len(c.Meshes))
+	for i, mesh := range c.Meshes {
+		clone.Meshes[i] = mesh.deepCloneFor(clone)
+	}
+
+	// cachedRenderable and each mesh's renderable are left nil so that
+	// GetRenderable builds a fresh Renderable, with its own VBOs, from
+	// the duplicated mesh data the next time it's called.
+	return clone
+}
+
+// deepCloneFor duplicates a ComponentMesh, including its SrcMesh's vertex
+// and index buffers, for use by Component.DeepClone.
+func (cm *ComponentMesh) deepCloneFor(parent *Component) *ComponentMesh {
+	clone := new(ComponentMesh)
+	clone.BinFile = cm.BinFile
+	clone.SrcFile = cm.SrcFile
+	clone.Textures = cm.Textures
+	clone.Offset = cm.Offset
+	clone.Parent = parent
+
+	if cm.SrcMesh != nil {
+		clone.SrcMesh = cloneGombzMesh(cm.SrcMesh)
+	}
+
+	return clone
+}
+
+// cloneGombzMesh makes a deep copy of a gombz.Mesh's attribute streams so
+// that mutating one copy's vertex data doesn't affect the other.
+func cloneGombzMesh(mesh *gombz.Mesh) *gombz.Mesh {
+	clone := new(gombz.Mesh)
+	*clone = *mesh
+
+	clone.Vertices = append([]mgl.Vec3(nil), mesh.Vertices...)
+	clone.Normals = append([]mgl.Vec3(nil), mesh.Normals...)
+	clone.Tangents = append([]mgl.Vec3(nil), mesh.Tangents...)
+	clone.UVs = append([]mgl.Vec2(nil), mesh.UVs...)
+	clone.Indices = append([]uint32(nil), mesh.Indices...)
+
+	return clone
+}
@@ -0,0 +1,231 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// CollisionShapeType identifies the concrete type of a CollisionShape and is
+// what discriminates the `Type` field in the component JSON.
+type CollisionShapeType string
+
+// The collision shape types supported by CollisionRef.
+const (
+	CollisionShapeTypeAABB       CollisionShapeType = "AABB"
+	CollisionShapeTypeSphere     CollisionShapeType = "Sphere"
+	CollisionShapeTypeCapsule    CollisionShapeType = "Capsule"
+	CollisionShapeTypeOBB        CollisionShapeType = "OBB"
+	CollisionShapeTypeConvexHull CollisionShapeType = "ConvexHull"
+	CollisionShapeTypeTriMesh    CollisionShapeType = "TriMesh"
+)
+
+// CollisionShape is implemented by every concrete collision shape a
+// CollisionRef can carry.
+type CollisionShape interface {
+	// ShapeType identifies which concrete shape this is.
+	ShapeType() CollisionShapeType
+}
+
+// AABBShape is an axis-aligned bounding box collision shape.
+type AABBShape struct {
+	Min mgl.Vec3
+	Max mgl.Vec3
+}
+
+// ShapeType implements CollisionShape.
+func (s AABBShape) ShapeType() CollisionShapeType { return CollisionShapeTypeAABB }
+
+// SphereShape is a sphere collision shape.
+type SphereShape struct {
+	Center mgl.Vec3
+	Radius float32
+}
+
+// ShapeType implements CollisionShape.
+func (s SphereShape) ShapeType() CollisionShapeType { return CollisionShapeTypeSphere }
+
+// CapsuleShape is a capsule collision shape: a cylinder of Radius between
+// points A and B, capped with hemispheres of the same radius.
+type CapsuleShape struct {
+	A      mgl.Vec3
+	B      mgl.Vec3
+	Radius float32
+}
+
+// ShapeType implements CollisionShape.
+func (s CapsuleShape) ShapeType() CollisionShapeType { return CollisionShapeTypeCapsule }
+
+// OBBShape is an oriented bounding box collision shape.
+type OBBShape struct {
+	Center      mgl.Vec3
+	HalfExtents mgl.Vec3
+	Orientation mgl.Quat
+}
+
+// ShapeType implements CollisionShape.
+func (s OBBShape) ShapeType() CollisionShapeType { return CollisionShapeTypeOBB }
+
+// ConvexHullShape is a convex hull collision shape described by its hull
+// points.
+type ConvexHullShape struct {
+	Points []mgl.Vec3
+}
+
+// ShapeType implements CollisionShape.
+func (s ConvexHullShape) ShapeType() CollisionShapeType { return CollisionShapeTypeConvexHull }
+
+// TriMeshShape is a concave triangle-mesh collision shape that reuses the
+// render geometry of one of the component's own meshes, identified by its
+// index into Component.Meshes.
+type TriMeshShape struct {
+	MeshIndex int
+}
+
+// ShapeType implements CollisionShape.
+func (s TriMeshShape) ShapeType() CollisionShapeType { return CollisionShapeTypeTriMesh }
+
+// CollisionRef specifies a collision object within the component
+// (e.g. a collision cube for a wall). Shape holds the concrete geometry --
+// see CollisionShape and its implementations (AABBShape, SphereShape,
+// CapsuleShape, OBBShape, ConvexHullShape, TriMeshShape).
+type CollisionRef struct {
+	Shape CollisionShape
+	Tags  []string
+}
+
+// MarshalJSON flattens the CollisionRef's Shape fields alongside a `Type`
+// discriminator and the Tags, rather than nesting the shape under its own
+// key, so that component JSON files stay easy to hand-author.
+func (cr CollisionRef) MarshalJSON() ([]byte, error) {
+	if cr.Shape == nil {
+		return nil, fmt.Errorf("component: CollisionRef has no Shape set")
+	}
+
+	shapeBytes, err := json.Marshal(cr.Shape)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(shapeBytes, &fields); err != nil {
+		return nil, err
+	}
+	fields["Type"] = string(cr.Shape.ShapeType())
+	fields["Tags"] = cr.Tags
+
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON reads the `Type` field to determine which concrete
+// CollisionShape to decode the rest of the object into. A missing `Type`
+// is treated as CollisionShapeTypeAABB so that component files predating
+// the introduction of other shapes keep loading unchanged.
+func (cr *CollisionRef) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Type CollisionShapeType
+		Tags []string
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+	cr.Tags = header.Tags
+
+	shapeType := header.Type
+	if shapeType == "" {
+		shapeType = CollisionShapeTypeAABB
+	}
+
+	switch shapeType {
+	case CollisionShapeTypeAABB:
+		var shape AABBShape
+		if err := json.Unmarshal(data, &shape); err != nil {
+			return err
+		}
+		cr.Shape = shape
+
+	case CollisionShapeTypeSphere:
+		var shape SphereShape
+		if err := json.Unmarshal(data, &shape); err != nil {
+			return err
+		}
+		cr.Shape = shape
+
+	case CollisionShapeTypeCapsule:
+		var shape CapsuleShape
+		if err := json.Unmarshal(data, &shape); err != nil {
+			return err
+		}
+		cr.Shape = shape
+
+	case CollisionShapeTypeOBB:
+		var shape OBBShape
+		if err := json.Unmarshal(data, &shape); err != nil {
+			return err
+		}
+		cr.Shape = shape
+
+	case CollisionShapeTypeConvexHull:
+		var shape ConvexHullShape
+		if err := json.Unmarshal(data, &shape); err != nil {
+			return err
+		}
+		cr.Shape = shape
+
+	case CollisionShapeTypeTriMesh:
+		var shape TriMeshShape
+		if err := json.Unmarshal(data, &shape); err != nil {
+			return err
+		}
+		cr.Shape = shape
+
+	default:
+		return fmt.Errorf("component: unknown collision shape type %q", header.Type)
+	}
+
+	return nil
+}
+
+// DefaultAABB derives an AABBShape from the mesh's vertex bounds, for
+// callers that want a reasonable default collision shape without having to
+// author one by hand.
+func (cm *ComponentMesh) DefaultAABB() (AABBShape, error) {
+	if cm.SrcMesh == nil || len(cm.SrcMesh.Vertices) == 0 {
+		return AABBShape{}, fmt.Errorf("component: mesh has no vertex data to derive an AABB from")
+	}
+
+	min := cm.SrcMesh.Vertices[0]
+	max := cm.SrcMesh.Vertices[0]
+	for _, v := range cm.SrcMesh.Vertices[1:] {
+		for axis := 0; axis < 3; axis++ {
+			if v[axis] < min[axis] {
+				min[axis] = v[axis]
+			}
+			if v[axis] > max[axis] {
+				max[axis] = v[axis]
+			}
+		}
+	}
+
+	return AABBShape{Min: min, Max: max}, nil
+}
+
+// DefaultConvexHull derives a ConvexHullShape from the mesh's vertex data
+// using the QuickHull algorithm, for callers that want a tight-fitting
+// default collision shape for a non-boxy mesh.
+func (cm *ComponentMesh) DefaultConvexHull() (ConvexHullShape, error) {
+	if cm.SrcMesh == nil || len(cm.SrcMesh.Vertices) == 0 {
+		return ConvexHullShape{}, fmt.Errorf("component: mesh has no vertex data to derive a convex hull from")
+	}
+
+	hullPoints, err := quickHull(cm.SrcMesh.Vertices)
+	if err != nil {
+		return ConvexHullShape{}, fmt.Errorf("component: failed to compute convex hull: %v", err)
+	}
+
+	return ConvexHullShape{Points: hullPoints}, nil
+}
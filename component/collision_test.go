@@ -0,0 +1,98 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCollisionRefJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  CollisionRef
+	}{
+		{"AABB", CollisionRef{
+			Shape: AABBShape{Min: mgl.Vec3{-1, -1, -1}, Max: mgl.Vec3{1, 1, 1}},
+			Tags:  []string{"solid"},
+		}},
+		{"Sphere", CollisionRef{
+			Shape: SphereShape{Center: mgl.Vec3{1, 2, 3}, Radius: 2.5},
+			Tags:  []string{"trigger"},
+		}},
+		{"Capsule", CollisionRef{
+			Shape: CapsuleShape{A: mgl.Vec3{0, 0, 0}, B: mgl.Vec3{0, 2, 0}, Radius: 0.5},
+		}},
+		{"OBB", CollisionRef{
+			Shape: OBBShape{Center: mgl.Vec3{1, 0, 0}, HalfExtents: mgl.Vec3{1, 2, 3}, Orientation: mgl.Quat{W: 1}},
+		}},
+		{"ConvexHull", CollisionRef{
+			Shape: ConvexHullShape{Points: []mgl.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}},
+		}},
+		{"TriMesh", CollisionRef{
+			Shape: TriMeshShape{MeshIndex: 2},
+			Tags:  []string{"static", "floor"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.ref)
+			if err != nil {
+				t.Fatalf("MarshalJSON failed: %v", err)
+			}
+
+			var roundTripped CollisionRef
+			if err := json.Unmarshal(data, &roundTripped); err != nil {
+				t.Fatalf("UnmarshalJSON failed: %v", err)
+			}
+
+			if roundTripped.Shape.ShapeType() != test.ref.Shape.ShapeType() {
+				t.Fatalf("expected shape type %q, got %q", test.ref.Shape.ShapeType(), roundTripped.Shape.ShapeType())
+			}
+			if !reflect.DeepEqual(roundTripped.Shape, test.ref.Shape) {
+				t.Errorf("shape data did not round-trip: got %+v, want %+v", roundTripped.Shape, test.ref.Shape)
+			}
+			if len(roundTripped.Tags) != len(test.ref.Tags) {
+				t.Errorf("tags did not round-trip: got %v, want %v", roundTripped.Tags, test.ref.Tags)
+			}
+			for i, tag := range test.ref.Tags {
+				if roundTripped.Tags[i] != tag {
+					t.Errorf("tag %d did not round-trip: got %q, want %q", i, roundTripped.Tags[i], tag)
+				}
+			}
+		})
+	}
+}
+
+func TestCollisionRefUnmarshalMissingTypeDefaultsToAABB(t *testing.T) {
+	data := []byte(`{"Min":[-1,-1,-1],"Max":[1,1,1]}`)
+
+	var ref CollisionRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+	if ref.Shape.ShapeType() != CollisionShapeTypeAABB {
+		t.Fatalf("expected a missing Type to default to AABB, got %q", ref.Shape.ShapeType())
+	}
+}
+
+func TestCollisionRefUnmarshalUnknownType(t *testing.T) {
+	data := []byte(`{"Type":"NotAShape"}`)
+
+	var ref CollisionRef
+	if err := json.Unmarshal(data, &ref); err == nil {
+		t.Fatal("expected an error for an unknown collision shape type, got nil")
+	}
+}
+
+func TestCollisionRefMarshalNilShape(t *testing.T) {
+	ref := CollisionRef{}
+	if _, err := json.Marshal(ref); err == nil {
+		t.Fatal("expected an error marshalling a CollisionRef with no Shape, got nil")
+	}
+}
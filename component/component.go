@@ -5,6 +5,7 @@ package component
 
 import (
 	"fmt"
+	"sync"
 
 	mgl "github.com/go-gl/mathgl/mgl32"
 	"github.com/tbogdala/fizzle"
@@ -18,6 +19,12 @@ type ComponentMesh struct {
 	// BinFile is a filepath should be relative to component file
 	BinFile string
 
+	// SrcFile is a filepath, relative to the component file, to a mesh
+	// authored in a third-party format (e.g. Wavefront OBJ or glTF 2.0).
+	// If set, it takes precedence over BinFile and is loaded through a
+	// MeshImporter registered for its file extension.
+	SrcFile string
+
 	// Textures specifies the texture files to load for mesh, relative
 	// to the component file
 	Textures []string
@@ -30,6 +37,10 @@ type ComponentMesh struct {
 
 	// SrcMesh is the cached mesh data either from SrcFile or BinFile
 	SrcMesh *gombz.Mesh
+
+	// renderable is the Renderable created for this mesh the last time
+	// GetRenderable ran, kept so that SetVertices can re-upload its VBO.
+	renderable *fizzle.Renderable
 }
 
 // ComponentChildRef defines a reference to another component JSON file
@@ -39,6 +50,33 @@ type ComponentChildRef struct {
 	Location mgl.Vec3
 }
 
+// Well-known texture slot names for ComponentMaterial.Textures. Client code
+// is free to use additional, arbitrary keys for shader-specific textures;
+// only the slots below are bound automatically by createRenderableForMesh.
+const (
+	TexSlotDiffuse   = "Diffuse"
+	TexSlotNormal    = "Normal"
+	TexSlotSpecular  = "Specular"
+	TexSlotRoughness = "Roughness"
+	TexSlotMetalness = "Metalness"
+	TexSlotEmissive  = "Emissive"
+	TexSlotAO        = "AO"
+	TexSlotOpacity   = "Opacity"
+)
+
+// MaterialTextureRef points a named material texture slot at a texture,
+// either one already loaded for the owning ComponentMesh or a standalone
+// file of its own.
+type MaterialTextureRef struct {
+	// TextureIndex, when non-nil, indexes into the owning ComponentMesh's
+	// Textures slice.
+	TextureIndex *int
+
+	// Path, used when TextureIndex is nil, is a texture file path of its
+	// own, relative to the component file.
+	Path string
+}
+
 // ComponentMaterial defines the material appearance of the component.
 type ComponentMaterial struct {
 	// ShaderName is the name of the shader program to use for rendering
@@ -46,15 +84,42 @@ type ComponentMaterial struct {
 
 	// Diffuse color for the material
 	Diffuse mgl.Vec4
+
+	// Textures maps a texture slot name -- one of the TexSlot* constants
+	// or an arbitrary user-defined key -- to the texture that should be
+	// bound for it.
+	Textures map[string]MaterialTextureRef
+
+	// SpecularPower is the specular exponent for the material.
+	SpecularPower float32
+
+	// Metallic is the metalness factor in the [0,1] range, used as a
+	// multiplier against the Metalness texture slot (or on its own if no
+	// texture is specified).
+	Metallic float32
+
+	// Roughness is the roughness factor in the [0,1] range, used as a
+	// multiplier against the Roughness texture slot (or on its own if no
+	// texture is specified).
+	Roughness float32
+
+	// EmissiveColor is the emissive color factor, used as a multiplier
+	// against the Emissive texture slot (or on its own if no texture is
+	// specified).
+	EmissiveColor mgl.Vec3
 }
 
-// CollisionRef specifies a collision object within the component
-// (e.g. a collision cube for a wall).
-// Note: right now it only supports AABB collisions.
-type CollisionRef struct {
-	Min  mgl.Vec3
-	Max  mgl.Vec3
-	Tags []string
+// materialTextureSlotOrder lists the well-known texture slots in the order
+// they're bound to the renderable's Core.TexN fields.
+var materialTextureSlotOrder = []string{
+	TexSlotDiffuse,
+	TexSlotNormal,
+	TexSlotSpecular,
+	TexSlotRoughness,
+	TexSlotMetalness,
+	TexSlotEmissive,
+	TexSlotAO,
+	TexSlotOpacity,
 }
 
 // Component is the main structure for component JSON files.
@@ -85,6 +150,20 @@ type Component struct {
 	// from JSON.
 	componentDirPath string
 
+	// vfsSource and vfsDir identify the resourceSource (and the directory
+	// within it) this component was loaded from via a Manager, if any.
+	// Components loaded directly through LoadComponentFromJSON (i.e. with
+	// no Manager involved) leave these nil/empty and every asset is
+	// resolved through componentDirPath against the local filesystem
+	// instead.
+	vfsSource resourceSource
+	vfsDir    string
+
+	// renderMu serializes GetRenderableAsync so that concurrent loads of the
+	// same Component don't race on cachedRenderable/SrcMesh/renderable; see
+	// GetRenderableAsync in async.go.
+	renderMu sync.Mutex
+
 	// this is the cached renerable object for the component that can
 	// be used as a prototype.
 	cachedRenderable *fizzle.Renderable
@@ -112,6 +191,8 @@ func (c *Component) Clone() *Component {
 	clone.Properties = c.Properties
 	clone.Material = c.Material
 	clone.componentDirPath = c.componentDirPath
+	clone.vfsSource = c.vfsSource
+	clone.vfsDir = c.vfsDir
 	clone.cachedRenderable = c.cachedRenderable
 
 	return clone
@@ -135,34 +216,51 @@ func (c *Component) GetRenderable(tm *fizzle.TextureManager, shaders map[string]
 	// comnponents only create new render nodes for the meshs defined and
 	// not for referenced components
 	for _, compMesh := range c.Meshes {
-		cmRenderable := createRenderableForMesh(tm, compMesh)
+		if compMesh.SrcMesh == nil {
+			if err := compMesh.LoadSrcMesh(); err != nil {
+				groggy.Log("ERROR", "GetRenderable failed to load mesh data for %s: %v", c.Name, err)
+			}
+		}
+
+		cmRenderable := createRenderableForMesh(tm, compMesh, c.Material)
+		compMesh.renderable = cmRenderable
 		group.AddChild(cmRenderable)
 
 		// assign material properties if specified
 		if c.Material != nil {
 			cmRenderable.Core.DiffuseColor = c.Material.Diffuse
+			cmRenderable.Core.SpecularPower = c.Material.SpecularPower
+			cmRenderable.Core.Metallic = c.Material.Metallic
+			cmRenderable.Core.Roughness = c.Material.Roughness
+			cmRenderable.Core.EmissiveColor = c.Material.EmissiveColor
 			cmRenderable.ShaderName = c.Material.ShaderName
 			loadedShader, okay := shaders[c.Material.ShaderName]
 			if okay {
 				cmRenderable.Core.Shader = loadedShader
 			}
 		}
-
-		// cache it for later
-		c.cachedRenderable = cmRenderable
 	}
 
+	// cache the group renderable, not just the last mesh's, so that
+	// subsequent calls return the whole component again.
+	c.cachedRenderable = group
+
 	return group
 }
 
-// GetFullBinFilePath returns the full file path for the mesh binary file (gombz format).
+// GetFullBinFilePath returns the full file path for the mesh binary file
+// (gombz format). If the owning component was loaded through a Manager, this
+// resolves against the mounted source's on-disk root (when it has one)
+// instead of always assuming componentDirPath is a local filesystem path.
 func (cm *ComponentMesh) GetFullBinFilePath() string {
-	return cm.Parent.componentDirPath + cm.BinFile
+	return cm.resolvePath(cm.BinFile)
 }
 
-// GetFullTexturePath returns the full file path for the mesh texture.
+// GetFullTexturePath returns the full file path for the mesh texture. See
+// GetFullBinFilePath for how the path is resolved for Manager-loaded
+// components.
 func (cm *ComponentMesh) GetFullTexturePath(textureIndex int) string {
-	return cm.Parent.componentDirPath + cm.Textures[textureIndex]
+	return cm.resolvePath(cm.Textures[textureIndex])
 }
 
 // GetVertices returns the vector slice containing the vertices for the mesh.
@@ -175,12 +273,16 @@ func (cm *ComponentMesh) GetVertices() ([]mgl.Vec3, error) {
 
 // createRenderableForMesh does the work of creating the Renderable and putting all of
 // the mesh data into VBOs.
-func createRenderableForMesh(tm *fizzle.TextureManager, compMesh *ComponentMesh) *fizzle.Renderable {
+func createRenderableForMesh(tm *fizzle.TextureManager, compMesh *ComponentMesh, material *ComponentMaterial) *fizzle.Renderable {
 	// create the new renderable
 	r := fizzle.CreateFromGombz(compMesh.SrcMesh)
 
-	// assign the texture
-	if len(compMesh.Textures) > 0 {
+	// if the material defines named texture slots, bind all of them;
+	// otherwise fall back to the legacy behavior of binding the first
+	// mesh texture as the diffuse map.
+	if material != nil && len(material.Textures) > 0 {
+		bindMaterialTextures(tm, r, compMesh, material)
+	} else if len(compMesh.Textures) > 0 {
 		var okay bool
 		r.Core.Tex0, okay = tm.GetTexture(compMesh.Textures[0])
 		if !okay {
@@ -190,3 +292,48 @@ func createRenderableForMesh(tm *fizzle.TextureManager, compMesh *ComponentMesh)
 
 	return r
 }
+
+// bindMaterialTextures resolves each named texture slot on material to a
+// texture, either through compMesh.Textures (by index) or a standalone path,
+// and binds it to the matching Core.TexN slot on r.
+func bindMaterialTextures(tm *fizzle.TextureManager, r *fizzle.Renderable, compMesh *ComponentMesh, material *ComponentMaterial) {
+	for slotIndex, slotName := range materialTextureSlotOrder {
+		ref, okay := material.Textures[slotName]
+		if !okay {
+			continue
+		}
+
+		texKey := ref.Path
+		if ref.TextureIndex != nil && *ref.TextureIndex >= 0 && *ref.TextureIndex < len(compMesh.Textures) {
+			texKey = compMesh.Textures[*ref.TextureIndex]
+		}
+		if len(texKey) == 0 {
+			continue
+		}
+
+		texID, found := tm.GetTexture(texKey)
+		if !found {
+			groggy.Log("ERROR", "createRenderableForMesh failed to assign a texture gl id for %s slot %s.", texKey, slotName)
+			continue
+		}
+
+		switch slotIndex {
+		case 0:
+			r.Core.Tex0 = texID
+		case 1:
+			r.Core.Tex1 = texID
+		case 2:
+			r.Core.Tex2 = texID
+		case 3:
+			r.Core.Tex3 = texID
+		case 4:
+			r.Core.Tex4 = texID
+		case 5:
+			r.Core.Tex5 = texID
+		case 6:
+			r.Core.Tex6 = texID
+		case 7:
+			r.Core.Tex7 = texID
+		}
+	}
+}
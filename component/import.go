@@ -0,0 +1,146 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/tbogdala/gombz"
+)
+
+// MeshImporter converts a third-party model file format into a gombz.Mesh
+// that the rest of the component system can consume, so that content authored
+// in a DCC tool (Blender, etc) can be loaded directly without first being
+// converted to the gombz binary format.
+type MeshImporter interface {
+	// Import loads the model located at path and returns the resulting mesh
+	// data, plus any texture paths the source file referenced (e.g. a
+	// diffuse map from an OBJ's .mtl file, or PBR maps from a glTF
+	// material), keyed by a TexSlot* constant and expressed relative to
+	// path's own directory. Textures are returned this way, rather than
+	// added as fields on gombz.Mesh, since this package doesn't own that
+	// type. Vertices, normals, UVs and (if present) tangents should be
+	// populated on the returned mesh; importers that can't source tangents
+	// from the file should calculate them instead of leaving them empty.
+	Import(path string) (mesh *gombz.Mesh, textures map[string]string, err error)
+}
+
+// meshImporters maps a lowercased file extension, including the leading dot,
+// to the MeshImporter responsible for loading it.
+var meshImporters = map[string]MeshImporter{
+	".obj":  new(objImporter),
+	".gltf": new(gltfImporter),
+	".glb":  new(gltfImporter),
+}
+
+// RegisterMeshImporter associates importer with ext (e.g. ".fbx") so that
+// SrcFile references with that extension get routed to it. This allows
+// client applications to add support for additional formats.
+func RegisterMeshImporter(ext string, importer MeshImporter) {
+	meshImporters[strings.ToLower(ext)] = importer
+}
+
+// ImportMesh loads the mesh data at path, choosing an importer based on the
+// file's extension. It returns an error if no importer is registered for the
+// extension or if the importer itself fails. The returned textures map (see
+// MeshImporter) is relative to path's own directory.
+func ImportMesh(path string) (*gombz.Mesh, map[string]string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	importer, okay := meshImporters[ext]
+	if !okay {
+		return nil, nil, fmt.Errorf("component: no mesh importer registered for file extension %q", ext)
+	}
+
+	mesh, textures, err := importer.Import(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("component: failed to import mesh %s: %v", path, err)
+	}
+
+	return mesh, textures, nil
+}
+
+// GetFullSrcFilePath returns the full file path for the mesh source file
+// (e.g. a .obj or .gltf file) referenced by SrcFile.
+func (cm *ComponentMesh) GetFullSrcFilePath() string {
+	return cm.resolvePath(cm.SrcFile)
+}
+
+// LoadSrcMesh loads the mesh data for the ComponentMesh into SrcMesh, sourcing
+// it from SrcFile (via a registered MeshImporter) if specified, or falling
+// back to the prebuilt gombz BinFile otherwise. It is a no-op if SrcMesh has
+// already been loaded.
+func (cm *ComponentMesh) LoadSrcMesh() error {
+	if cm.SrcMesh != nil {
+		return nil
+	}
+
+	if len(cm.SrcFile) > 0 {
+		// third-party importers (OBJ, glTF) need a real filesystem path,
+		// since they in turn resolve sibling files of their own (a .mtl,
+		// external glTF buffers/images) relative to it.
+		srcPath, okay := cm.resolveOSPath(cm.SrcFile)
+		if !okay {
+			return fmt.Errorf("component: SrcFile %q requires an on-disk source, but %s has no filesystem root (e.g. it's inside a mounted zip archive)", cm.SrcFile, cm.Parent.Name)
+		}
+
+		mesh, textures, err := ImportMesh(srcPath)
+		if err != nil {
+			return err
+		}
+		cm.SrcMesh = mesh
+		cm.addImportedTextures(textures)
+		return nil
+	}
+
+	reader, err := cm.openAsset(cm.BinFile)
+	if err != nil {
+		return fmt.Errorf("component: failed to open gombz binary %s: %v", cm.BinFile, err)
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("component: failed to read gombz binary %s: %v", cm.BinFile, err)
+	}
+
+	mesh, err := gombz.Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("component: failed to parse gombz binary %s: %v", cm.BinFile, err)
+	}
+	cm.SrcMesh = mesh
+
+	return nil
+}
+
+// addImportedTextures appends any texture paths a MeshImporter discovered
+// (relative to cm.SrcFile's own directory) to cm.Textures, re-rooted to be
+// relative to the component file like the rest of cm.Textures, and skipping
+// any that are already present.
+func (cm *ComponentMesh) addImportedTextures(textures map[string]string) {
+	srcDir := filepath.Dir(filepath.FromSlash(cm.SrcFile))
+
+	for _, slot := range materialTextureSlotOrder {
+		texPath, found := textures[slot]
+		if !found || texPath == "" {
+			continue
+		}
+		if srcDir != "." {
+			texPath = filepath.ToSlash(filepath.Join(srcDir, texPath))
+		}
+
+		alreadyPresent := false
+		for _, existing := range cm.Textures {
+			if existing == texPath {
+				alreadyPresent = true
+				break
+			}
+		}
+		if !alreadyPresent {
+			cm.Textures = append(cm.Textures, texPath)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/gombz"
+)
+
+// gltfImporter is a MeshImporter that loads glTF 2.0 assets, either the
+// JSON-plus-external-resources form (.gltf) or the single-file binary form
+// (.glb). Only the first primitive of the document's first mesh is imported
+// (scenes and nodes are not read), which covers the common case of one
+// source file per ComponentMesh.
+type gltfImporter struct{}
+
+// gltfDocument mirrors the subset of the glTF 2.0 schema needed to pull
+// vertex attributes, indices and PBR texture references out of a document.
+type gltfDocument struct {
+	Buffers     []gltfBuffer     `json:"buffers"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Materials   []gltfMaterial   `json:"materials"`
+	Textures    []gltfTexture    `json:"textures"`
+	Images      []gltfImage      `json:"images"`
+}
+
+type gltfBuffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type gltfAccessor struct {
+	BufferView    int    `json:"bufferView"`
+	ByteOffset    int    `json:"byteOffset"`
+	ComponentType int    `json:"componentType"`
+	Count         int    `json:"count"`
+	Type          string `json:"type"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+}
+
+type gltfMaterial struct {
+	PbrMetallicRoughness struct {
+		BaseColorTexture         *gltfTextureRef `json:"baseColorTexture"`
+		MetallicRoughnessTexture *gltfTextureRef `json:"metallicRoughnessTexture"`
+	} `json:"pbrMetallicRoughness"`
+	NormalTexture    *gltfTextureRef `json:"normalTexture"`
+	EmissiveTexture  *gltfTextureRef `json:"emissiveTexture"`
+	OcclusionTexture *gltfTextureRef `json:"occlusionTexture"`
+}
+
+type gltfTextureRef struct {
+	Index int `json:"index"`
+}
+
+type gltfTexture struct {
+	Source int `json:"source"`
+}
+
+type gltfImage struct {
+	URI string `json:"uri"`
+}
+
+// glTF accessor componentType constants (see the glTF 2.0 spec).
+const (
+	gltfComponentFloat = 5126
+)
+
+// Import loads the glTF document at path -- JSON (.gltf) or binary (.glb) --
+// and converts its first mesh primitive into a gombz.Mesh, plus any PBR
+// texture paths its material references, keyed by TexSlot* and expressed
+// relative to path's own directory (see MeshImporter).
+func (gi *gltfImporter) Import(path string) (*gombz.Mesh, map[string]string, error) {
+	jsonBytes, binChunk, err := readGltfChunks(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc gltfDocument
+	if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse glTF JSON in %s: %v", path, err)
+	}
+	if len(doc.Meshes) == 0 || len(doc.Meshes[0].Primitives) == 0 {
+		return nil, nil, fmt.Errorf("no mesh primitives found in %s", path)
+	}
+
+	buffers, err := loadGltfBuffers(doc, filepath.Dir(path), binChunk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prim := doc.Meshes[0].Primitives[0]
+	mesh := new(gombz.Mesh)
+
+	posAccessor, okay := prim.Attributes["POSITION"]
+	if !okay {
+		return nil, nil, fmt.Errorf("glTF primitive in %s has no POSITION attribute", path)
+	}
+	mesh.Vertices, err = readGltfVec3(doc, buffers, posAccessor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if nrmAccessor, okay := prim.Attributes["NORMAL"]; okay {
+		mesh.Normals, err = readGltfVec3(doc, buffers, nrmAccessor)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if uvAccessor, okay := prim.Attributes["TEXCOORD_0"]; okay {
+		mesh.UVs, err = readGltfVec2(doc, buffers, uvAccessor)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if tanAccessor, okay := prim.Attributes["TANGENT"]; okay {
+		mesh.Tangents, err = readGltfVec3(doc, buffers, tanAccessor)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if prim.Indices != nil {
+		mesh.Indices, err = readGltfIndices(doc, buffers, *prim.Indices)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		mesh.Indices = make([]uint32, len(mesh.Vertices))
+		for i := range mesh.Indices {
+			mesh.Indices[i] = uint32(i)
+		}
+	}
+
+	mesh.VertexCount = int32(len(mesh.Vertices))
+	mesh.FaceCount = int32(len(mesh.Indices) / 3)
+
+	textures := make(map[string]string)
+	if prim.Material != nil && *prim.Material < len(doc.Materials) {
+		textures = gltfMaterialTextures(doc, *prim.Material)
+	}
+
+	// NORMAL is optional per the glTF 2.0 spec; without it there's nothing
+	// sound to build tangents from here (a real implementation would
+	// synthesize flat normals first), so just leave Tangents empty rather
+	// than indexing past an empty Normals slice.
+	if len(mesh.Tangents) == 0 && len(mesh.UVs) > 0 && len(mesh.Normals) == len(mesh.Vertices) {
+		mesh.Tangents = calculateTangents(mesh.Vertices, mesh.Normals, mesh.UVs, mesh.Indices)
+	}
+
+	return mesh, textures, nil
+}
+
+// gltfMaterialTextures resolves the texture image paths referenced by the
+// glTF material at materialIndex, keyed by TexSlot* and left relative to
+// the glTF document's own directory; re-rooting them against the owning
+// ComponentMesh is the caller's job (see ComponentMesh.addImportedTextures).
+func gltfMaterialTextures(doc gltfDocument, materialIndex int) map[string]string {
+	mat := doc.Materials[materialIndex]
+	resolve := func(ref *gltfTextureRef) string {
+		if ref == nil || ref.Index >= len(doc.Textures) {
+			return ""
+		}
+		imgIndex := doc.Textures[ref.Index].Source
+		if imgIndex >= len(doc.Images) {
+			return ""
+		}
+		uri := doc.Images[imgIndex].URI
+		if uri == "" || strings.HasPrefix(uri, "data:") {
+			return ""
+		}
+		return uri
+	}
+
+	textures := make(map[string]string)
+	set := func(slot string, value string) {
+		if value != "" {
+			textures[slot] = value
+		}
+	}
+
+	set(TexSlotDiffuse, resolve(mat.PbrMetallicRoughness.BaseColorTexture))
+	set(TexSlotNormal, resolve(mat.NormalTexture))
+	set(TexSlotRoughness, resolve(mat.PbrMetallicRoughness.MetallicRoughnessTexture))
+	set(TexSlotEmissive, resolve(mat.EmissiveTexture))
+	set(TexSlotAO, resolve(mat.OcclusionTexture))
+
+	return textures
+}
+
+// readGltfChunks splits a .glb file into its JSON and binary chunks, or for
+// a plain .gltf file returns the file's bytes as the JSON chunk with no
+// binary chunk.
+func readGltfChunks(path string) (jsonBytes []byte, binChunk []byte, err error) {
+	if strings.ToLower(filepath.Ext(path)) != ".glb" {
+		jsonBytes, err = ioutil.ReadFile(path)
+		return jsonBytes, nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var header struct {
+		Magic   uint32
+		Version uint32
+		Length  uint32
+	}
+	if err := binary.Read(file, binary.LittleEndian, &header); err != nil {
+		return nil, nil, fmt.Errorf("unable to read glb header: %v", err)
+	}
+
+	for {
+		var chunkLength, chunkType uint32
+		if err := binary.Read(file, binary.LittleEndian, &chunkLength); err != nil {
+			break
+		}
+		if err := binary.Read(file, binary.LittleEndian, &chunkType); err != nil {
+			break
+		}
+		chunk := make([]byte, chunkLength)
+		if _, err := io.ReadFull(file, chunk); err != nil {
+			return nil, nil, fmt.Errorf("unable to read glb chunk: %v", err)
+		}
+		switch chunkType {
+		case 0x4E4F534A: // "JSON"
+			jsonBytes = chunk
+		case 0x004E4942: // "BIN\0"
+			binChunk = chunk
+		}
+	}
+
+	if jsonBytes == nil {
+		return nil, nil, fmt.Errorf("glb file %s has no JSON chunk", path)
+	}
+
+	return jsonBytes, binChunk, nil
+}
+
+// loadGltfBuffers resolves each glTF buffer to its raw bytes, decoding
+// embedded base64 data URIs, reading external files relative to baseDir, or
+// using the glb binary chunk for the buffer with no URI.
+func loadGltfBuffers(doc gltfDocument, baseDir string, binChunk []byte) ([][]byte, error) {
+	buffers := make([][]byte, len(doc.Buffers))
+	for i, buf := range doc.Buffers {
+		switch {
+		case buf.URI == "":
+			buffers[i] = binChunk
+
+		case strings.HasPrefix(buf.URI, "data:"):
+			commaIndex := strings.IndexByte(buf.URI, ',')
+			if commaIndex < 0 {
+				return nil, fmt.Errorf("malformed data URI for buffer %d", i)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(buf.URI[commaIndex+1:])
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode embedded buffer %d: %v", i, err)
+			}
+			buffers[i] = decoded
+
+		default:
+			data, err := ioutil.ReadFile(filepath.Join(baseDir, buf.URI))
+			if err != nil {
+				return nil, fmt.Errorf("unable to read external buffer %s: %v", buf.URI, err)
+			}
+			buffers[i] = data
+		}
+	}
+
+	return buffers, nil
+}
+
+// accessorBytes returns the raw bytes backing a glTF accessor (from its
+// byte offset onward), along with the accessor and bufferView themselves,
+// after validating every index involved so a malformed/truncated glTF
+// returns an error instead of panicking.
+func accessorBytes(doc gltfDocument, buffers [][]byte, accessorIndex int) ([]byte, gltfAccessor, gltfBufferView, error) {
+	if accessorIndex < 0 || accessorIndex >= len(doc.Accessors) {
+		return nil, gltfAccessor{}, gltfBufferView{}, fmt.Errorf("accessor index %d out of range", accessorIndex)
+	}
+	accessor := doc.Accessors[accessorIndex]
+
+	if accessor.BufferView < 0 || accessor.BufferView >= len(doc.BufferViews) {
+		return nil, gltfAccessor{}, gltfBufferView{}, fmt.Errorf("accessor %d: bufferView index %d out of range", accessorIndex, accessor.BufferView)
+	}
+	view := doc.BufferViews[accessor.BufferView]
+
+	if view.Buffer < 0 || view.Buffer >= len(buffers) {
+		return nil, gltfAccessor{}, gltfBufferView{}, fmt.Errorf("accessor %d: buffer index %d out of range", accessorIndex, view.Buffer)
+	}
+	buffer := buffers[view.Buffer]
+
+	start := view.ByteOffset + accessor.ByteOffset
+	if start < 0 || start > len(buffer) {
+		return nil, gltfAccessor{}, gltfBufferView{}, fmt.Errorf("accessor %d: byte offset %d out of range for a buffer of length %d", accessorIndex, start, len(buffer))
+	}
+
+	return buffer[start:], accessor, view, nil
+}
+
+// elementStride returns the byte distance between consecutive elements of
+// an accessor backed by view: view.ByteStride when the bufferView is
+// interleaved (shared by more than one attribute), or the tightly-packed
+// elemSize otherwise.
+func elementStride(view gltfBufferView, elemSize int) int {
+	if view.ByteStride != 0 {
+		return view.ByteStride
+	}
+	return elemSize
+}
+
+// readAccessorElement returns the elemSize-byte slice of data for element i,
+// honoring stride, after bounds-checking it fits within data.
+func readAccessorElement(data []byte, stride, elemSize, i int) ([]byte, error) {
+	offset := i * stride
+	if offset < 0 || offset+elemSize > len(data) {
+		return nil, fmt.Errorf("element %d is out of range for its backing buffer", i)
+	}
+	return data[offset : offset+elemSize], nil
+}
+
+// readGltfVec3 decodes an accessor of type VEC3/float into a slice of Vec3.
+func readGltfVec3(doc gltfDocument, buffers [][]byte, accessorIndex int) ([]mgl.Vec3, error) {
+	data, accessor, view, err := accessorBytes(doc, buffers, accessorIndex)
+	if err != nil {
+		return nil, err
+	}
+	if accessor.ComponentType != gltfComponentFloat {
+		return nil, fmt.Errorf("accessor %d: only float components are supported", accessorIndex)
+	}
+
+	const elemSize = 12
+	stride := elementStride(view, elemSize)
+	result := make([]mgl.Vec3, accessor.Count)
+	for i := range result {
+		elem, err := readAccessorElement(data, stride, elemSize, i)
+		if err != nil {
+			return nil, fmt.Errorf("accessor %d: %v", accessorIndex, err)
+		}
+		result[i] = mgl.Vec3{
+			readFloat32(elem, 0),
+			readFloat32(elem, 4),
+			readFloat32(elem, 8),
+		}
+	}
+
+	return result, nil
+}
+
+// readGltfVec2 decodes an accessor of type VEC2/float into a slice of Vec2.
+func readGltfVec2(doc gltfDocument, buffers [][]byte, accessorIndex int) ([]mgl.Vec2, error) {
+	data, accessor, view, err := accessorBytes(doc, buffers, accessorIndex)
+	if err != nil {
+		return nil, err
+	}
+	if accessor.ComponentType != gltfComponentFloat {
+		return nil, fmt.Errorf("accessor %d: only float components are supported", accessorIndex)
+	}
+
+	const elemSize = 8
+	stride := elementStride(view, elemSize)
+	result := make([]mgl.Vec2, accessor.Count)
+	for i := range result {
+		elem, err := readAccessorElement(data, stride, elemSize, i)
+		if err != nil {
+			return nil, fmt.Errorf("accessor %d: %v", accessorIndex, err)
+		}
+		result[i] = mgl.Vec2{
+			readFloat32(elem, 0),
+			readFloat32(elem, 4),
+		}
+	}
+
+	return result, nil
+}
+
+// readGltfIndices decodes an index accessor (unsigned byte/short/int) into
+// a slice of uint32, the index width gombz expects.
+func readGltfIndices(doc gltfDocument, buffers [][]byte, accessorIndex int) ([]uint32, error) {
+	data, accessor, view, err := accessorBytes(doc, buffers, accessorIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var elemSize int
+	switch accessor.ComponentType {
+	case 5121: // UNSIGNED_BYTE
+		elemSize = 1
+	case 5123: // UNSIGNED_SHORT
+		elemSize = 2
+	case 5125: // UNSIGNED_INT
+		elemSize = 4
+	default:
+		return nil, fmt.Errorf("accessor %d: unsupported index component type %d", accessorIndex, accessor.ComponentType)
+	}
+	stride := elementStride(view, elemSize)
+
+	result := make([]uint32, accessor.Count)
+	for i := range result {
+		elem, err := readAccessorElement(data, stride, elemSize, i)
+		if err != nil {
+			return nil, fmt.Errorf("accessor %d: %v", accessorIndex, err)
+		}
+		switch accessor.ComponentType {
+		case 5121:
+			result[i] = uint32(elem[0])
+		case 5123:
+			result[i] = uint32(binary.LittleEndian.Uint16(elem))
+		case 5125:
+			result[i] = binary.LittleEndian.Uint32(elem)
+		}
+	}
+
+	return result, nil
+}
+
+// readFloat32 decodes a little-endian float32 at byte offset off.
+func readFloat32(data []byte, off int) float32 {
+	bits := binary.LittleEndian.Uint32(data[off:])
+	return math.Float32frombits(bits)
+}
@@ -0,0 +1,228 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/gombz"
+)
+
+// objImporter is a MeshImporter that loads Wavefront OBJ files, including
+// the diffuse texture referenced by a sibling .mtl file.
+type objImporter struct{}
+
+// objVertexKey identifies a unique position/uv/normal combination so that
+// OBJ's per-face vertex indices can be converted into gombz's single
+// index buffer per attribute stream.
+type objVertexKey struct {
+	posIndex int
+	uvIndex  int
+	nrmIndex int
+}
+
+// Import loads the OBJ file at path and returns the resulting gombz.Mesh,
+// plus any texture paths discovered via a referenced .mtl file, keyed by
+// TexSlot* and expressed relative to path's own directory (it's up to the
+// caller to re-root that against the owning ComponentMesh).
+func (oi *objImporter) Import(path string) (*gombz.Mesh, map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var rawPositions, rawNormals []mgl.Vec3
+	var rawUVs []mgl.Vec2
+	textures := make(map[string]string)
+
+	mesh := new(gombz.Mesh)
+	indexForKey := make(map[objVertexKey]uint32)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "v":
+			v, err := parseVec3(fields[1:])
+			if err != nil {
+				return nil, nil, fmt.Errorf("bad vertex line %q: %v", line, err)
+			}
+			rawPositions = append(rawPositions, v)
+
+		case "vn":
+			n, err := parseVec3(fields[1:])
+			if err != nil {
+				return nil, nil, fmt.Errorf("bad normal line %q: %v", line, err)
+			}
+			rawNormals = append(rawNormals, n)
+
+		case "vt":
+			if len(fields) < 3 {
+				return nil, nil, fmt.Errorf("bad uv line %q: expected 2 components", line)
+			}
+			u, err := strconv.ParseFloat(fields[1], 32)
+			if err != nil {
+				return nil, nil, fmt.Errorf("bad uv line %q: %v", line, err)
+			}
+			v, err := strconv.ParseFloat(fields[2], 32)
+			if err != nil {
+				return nil, nil, fmt.Errorf("bad uv line %q: %v", line, err)
+			}
+			rawUVs = append(rawUVs, mgl.Vec2{float32(u), float32(v)})
+
+		case "f":
+			// triangulate the face as a fan, which correctly handles both
+			// triangles and convex quads.
+			faceIndices := make([]uint32, 0, len(fields)-1)
+			for _, vertSpec := range fields[1:] {
+				idx, err := resolveObjVertex(vertSpec, rawPositions, rawUVs, rawNormals, mesh, indexForKey)
+				if err != nil {
+					return nil, nil, err
+				}
+				faceIndices = append(faceIndices, idx)
+			}
+			for i := 1; i < len(faceIndices)-1; i++ {
+				mesh.Indices = append(mesh.Indices, faceIndices[0], faceIndices[i], faceIndices[i+1])
+			}
+
+		case "mtllib":
+			if len(fields) < 2 {
+				return nil, nil, fmt.Errorf("bad mtllib line %q: expected a filename", line)
+			}
+			mtlPath := filepath.Join(filepath.Dir(path), fields[1])
+			tex, err := loadMtlDiffuseTexture(mtlPath)
+			if err == nil {
+				textures[TexSlotDiffuse] = tex
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	mesh.VertexCount = int32(len(mesh.Vertices))
+	mesh.FaceCount = int32(len(mesh.Indices) / 3)
+
+	if len(mesh.Tangents) == 0 && len(mesh.UVs) > 0 && len(mesh.Normals) == len(mesh.Vertices) {
+		mesh.Tangents = calculateTangents(mesh.Vertices, mesh.Normals, mesh.UVs, mesh.Indices)
+	}
+
+	return mesh, textures, nil
+}
+
+// resolveObjVertex parses a single `f` vertex spec (e.g. "3/4/5", "3//5" or
+// "3") and returns the gombz index for it, creating new entries in the
+// mesh's attribute streams the first time a unique combination is seen.
+func resolveObjVertex(spec string, positions []mgl.Vec3, uvs []mgl.Vec2, normals []mgl.Vec3, mesh *gombz.Mesh, indexForKey map[objVertexKey]uint32) (uint32, error) {
+	parts := strings.Split(spec, "/")
+
+	posIdx, err := parseObjIndex(parts[0], len(positions))
+	if err != nil {
+		return 0, fmt.Errorf("bad face vertex %q: %v", spec, err)
+	}
+
+	uvIdx := -1
+	if len(parts) > 1 && parts[1] != "" {
+		uvIdx, err = parseObjIndex(parts[1], len(uvs))
+		if err != nil {
+			return 0, fmt.Errorf("bad face uv %q: %v", spec, err)
+		}
+	}
+
+	nrmIdx := -1
+	if len(parts) > 2 && parts[2] != "" {
+		nrmIdx, err = parseObjIndex(parts[2], len(normals))
+		if err != nil {
+			return 0, fmt.Errorf("bad face normal %q: %v", spec, err)
+		}
+	}
+
+	key := objVertexKey{posIndex: posIdx, uvIndex: uvIdx, nrmIndex: nrmIdx}
+	if idx, okay := indexForKey[key]; okay {
+		return idx, nil
+	}
+
+	newIndex := uint32(len(mesh.Vertices))
+	mesh.Vertices = append(mesh.Vertices, positions[posIdx])
+	if uvIdx >= 0 {
+		mesh.UVs = append(mesh.UVs, uvs[uvIdx])
+	} else {
+		mesh.UVs = append(mesh.UVs, mgl.Vec2{})
+	}
+	if nrmIdx >= 0 {
+		mesh.Normals = append(mesh.Normals, normals[nrmIdx])
+	} else {
+		mesh.Normals = append(mesh.Normals, mgl.Vec3{})
+	}
+	indexForKey[key] = newIndex
+
+	return newIndex, nil
+}
+
+// parseObjIndex converts an OBJ 1-based (or negative, relative-to-end)
+// index string into a 0-based slice index, returning an error if the result
+// doesn't land within [0, count).
+func parseObjIndex(s string, count int) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		i = count + i
+	} else {
+		i = i - 1
+	}
+	if i < 0 || i >= count {
+		return 0, fmt.Errorf("index %s resolves to %d, out of range [0, %d)", s, i, count)
+	}
+	return i, nil
+}
+
+// parseVec3 converts three whitespace-split fields into a mgl.Vec3.
+func parseVec3(fields []string) (mgl.Vec3, error) {
+	if len(fields) < 3 {
+		return mgl.Vec3{}, fmt.Errorf("expected 3 components, got %d", len(fields))
+	}
+	var v mgl.Vec3
+	for i := 0; i < 3; i++ {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return mgl.Vec3{}, err
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
+
+// loadMtlDiffuseTexture scans an OBJ .mtl file for the first `map_Kd`
+// directive and returns the texture filename it references.
+func loadMtlDiffuseTexture(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.TrimSpace(scanner.Text()))
+		if len(fields) >= 2 && fields[0] == "map_Kd" {
+			return fields[len(fields)-1], nil
+		}
+	}
+
+	return "", fmt.Errorf("no map_Kd directive found in %s", path)
+}
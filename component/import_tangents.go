@@ -0,0 +1,51 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// calculateTangents derives a per-vertex tangent stream from the given
+// positions, normals, UVs and triangle index buffer, for importers whose
+// source format doesn't supply tangents directly. It uses the standard
+// Lengyel method of accumulating a tangent per triangle and then averaging
+// and orthogonalizing it against the vertex normal.
+func calculateTangents(vertices, normals []mgl.Vec3, uvs []mgl.Vec2, indices []uint32) []mgl.Vec3 {
+	tangents := make([]mgl.Vec3, len(vertices))
+
+	for i := 0; i+2 < len(indices); i += 3 {
+		i0, i1, i2 := indices[i], indices[i+1], indices[i+2]
+
+		edge1 := vertices[i1].Sub(vertices[i0])
+		edge2 := vertices[i2].Sub(vertices[i0])
+		deltaUV1 := uvs[i1].Sub(uvs[i0])
+		deltaUV2 := uvs[i2].Sub(uvs[i0])
+
+		denom := deltaUV1[0]*deltaUV2[1] - deltaUV2[0]*deltaUV1[1]
+		if denom == 0 {
+			continue
+		}
+		r := 1.0 / denom
+
+		tangent := edge1.Mul(deltaUV2[1]).Sub(edge2.Mul(deltaUV1[1])).Mul(r)
+		tangents[i0] = tangents[i0].Add(tangent)
+		tangents[i1] = tangents[i1].Add(tangent)
+		tangents[i2] = tangents[i2].Add(tangent)
+	}
+
+	for i := range tangents {
+		n := normals[i]
+		t := tangents[i]
+		// Gram-Schmidt orthogonalize against the normal.
+		t = t.Sub(n.Mul(n.Dot(t)))
+		if t.Len() > 0.00001 {
+			tangents[i] = t.Normalize()
+		} else {
+			tangents[i] = mgl.Vec3{1, 0, 0}
+		}
+	}
+
+	return tangents
+}
@@ -0,0 +1,308 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tbogdala/groggy"
+)
+
+// resourceSource is a read-only view over a directory tree or a mounted zip
+// archive that resource groups scan for assets.
+type resourceSource interface {
+	// Open returns the contents of relPath, which is always '/'-separated
+	// and relative to the source's root.
+	Open(relPath string) (io.ReadCloser, error)
+
+	// Walk invokes fn with the relative path of every file in the source
+	// whose name ends in suffix.
+	Walk(suffix string, fn func(relPath string) error) error
+
+	// Close releases any resources (e.g. an open zip archive) held by
+	// the source.
+	Close() error
+
+	// RootDir returns the real on-disk directory backing this source, and
+	// true, for sources that have one (a mounted directory). Sources with
+	// no filesystem root of their own, such as a mounted zip archive,
+	// return ("", false).
+	RootDir() (string, bool)
+}
+
+// dirSource is a resourceSource backed by a plain directory on disk.
+type dirSource struct {
+	root string
+}
+
+func (d *dirSource) Open(relPath string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(d.root, filepath.FromSlash(relPath)))
+}
+
+func (d *dirSource) Walk(suffix string, fn func(relPath string) error) error {
+	return filepath.Walk(d.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, suffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel))
+	})
+}
+
+func (d *dirSource) Close() error {
+	return nil
+}
+
+func (d *dirSource) RootDir() (string, bool) {
+	return d.root, true
+}
+
+// zipSource is a resourceSource backed by a mounted zip archive, letting a
+// game ship components, meshes and textures in a single file.
+type zipSource struct {
+	archive *zip.ReadCloser
+}
+
+func (z *zipSource) Open(relPath string) (io.ReadCloser, error) {
+	relPath = filepath.ToSlash(relPath)
+	for _, f := range z.archive.File {
+		if f.Name == relPath {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("component: %s not found in zip archive", relPath)
+}
+
+func (z *zipSource) Walk(suffix string, fn func(relPath string) error) error {
+	for _, f := range z.archive.File {
+		if f.FileInfo().IsDir() || !strings.HasSuffix(f.Name, suffix) {
+			continue
+		}
+		if err := fn(f.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (z *zipSource) Close() error {
+	return z.archive.Close()
+}
+
+func (z *zipSource) RootDir() (string, bool) {
+	return "", false
+}
+
+// libraryEntry tracks where a named component was found and the loaded
+// instance once it has been requested at least once.
+type libraryEntry struct {
+	source   resourceSource
+	relPath  string
+	loaded   *Component
+	refCount int
+}
+
+// ResourceGroup is a named collection of mounted directories and zip
+// archives that components and the meshes/textures they reference are
+// resolved and loaded from. Grouping lets client code free everything
+// belonging to a level or zone in one call to Manager.Unload.
+type ResourceGroup struct {
+	name    string
+	sources []resourceSource
+	index   map[string]*libraryEntry
+}
+
+// Manager is a resource library that owns one or more named ResourceGroups,
+// indexing every *.component file found in their mounted sources by name
+// so that ComponentChildRef.File lookups don't need to know the raw
+// relative path of the file on disk (or inside a zip). Manager is safe for
+// concurrent use -- mu guards groups and everything reachable from them --
+// so a background goroutine can call Get/Release to stream components in
+// while the render loop touches the same group.
+type Manager struct {
+	mu     sync.Mutex
+	groups map[string]*ResourceGroup
+}
+
+// NewManager creates a new, empty resource Manager.
+func NewManager() *Manager {
+	return &Manager{
+		groups: make(map[string]*ResourceGroup),
+	}
+}
+
+// group returns (creating if necessary) the named ResourceGroup.
+func (m *Manager) group(name string) *ResourceGroup {
+	g, okay := m.groups[name]
+	if !okay {
+		g = &ResourceGroup{
+			name:  name,
+			index: make(map[string]*libraryEntry),
+		}
+		m.groups[name] = g
+	}
+	return g
+}
+
+// MountDirectory adds the directory at path as a source for group, scanning
+// it recursively for *.component files and indexing them by name (the file's
+// base name with the .component extension stripped).
+func (m *Manager) MountDirectory(group string, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	src := &dirSource{root: path}
+	return m.mount(group, src)
+}
+
+// MountZip adds the zip archive at path as a source for group, scanning its
+// contents for *.component entries and indexing them the same way a mounted
+// directory would be.
+func (m *Manager) MountZip(group string, path string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("component: failed to open zip archive %s: %v", path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.mount(group, &zipSource{archive: archive})
+}
+
+// mount adds src to group and indexes every *.component file it contains.
+func (m *Manager) mount(group string, src resourceSource) error {
+	g := m.group(group)
+	g.sources = append(g.sources, src)
+
+	return src.Walk(".component", func(relPath string) error {
+		name := componentNameFromPath(relPath)
+		g.index[name] = &libraryEntry{source: src, relPath: relPath}
+		return nil
+	})
+}
+
+// componentNameFromPath derives the index name for a component file: its
+// base name with the .component extension removed.
+func componentNameFromPath(relPath string) string {
+	base := filepath.Base(relPath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Get loads (if it hasn't been loaded already) and returns the component
+// called name from group, incrementing its reference count. Callers should
+// treat the returned Component as shared and call Manager.Release when
+// they're done with it.
+func (m *Manager) Get(group string, name string) (*Component, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, okay := m.groups[group]
+	if !okay {
+		return nil, fmt.Errorf("component: resource group %q has not been mounted", group)
+	}
+
+	entry, okay := g.index[name]
+	if !okay {
+		return nil, fmt.Errorf("component: no component named %q found in group %q", name, group)
+	}
+
+	if entry.loaded == nil {
+		reader, err := entry.source.Open(entry.relPath)
+		if err != nil {
+			return nil, fmt.Errorf("component: failed to open %s: %v", entry.relPath, err)
+		}
+		defer reader.Close()
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("component: failed to read %s: %v", entry.relPath, err)
+		}
+
+		relDir := filepath.ToSlash(filepath.Dir(entry.relPath))
+		if relDir == "." {
+			relDir = ""
+		}
+
+		loaded, err := LoadComponentFromJSON(data, relDir+"/")
+		if err != nil {
+			return nil, err
+		}
+		// route every asset this component references (BinFile, SrcFile,
+		// Textures) back through the same source it was found in, so that
+		// directory-mounted and zip-mounted groups both work.
+		loaded.vfsSource = entry.source
+		loaded.vfsDir = relDir
+		entry.loaded = loaded
+	}
+
+	entry.refCount++
+	return entry.loaded, nil
+}
+
+// ResolveChildRef resolves a ComponentChildRef within group, loading (and
+// reference-counting) the referenced component through the index rather
+// than treating ref.File as a raw relative path.
+func (m *Manager) ResolveChildRef(group string, ref *ComponentChildRef) (*Component, error) {
+	return m.Get(group, componentNameFromPath(ref.File))
+}
+
+// Release decrements the reference count for the named component in group.
+// It does not unload anything by itself -- use Unload to free a whole
+// group's worth of components at once, e.g. on a level transition.
+func (m *Manager) Release(group string, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, okay := m.groups[group]
+	if !okay {
+		return
+	}
+	entry, okay := g.index[name]
+	if !okay || entry.refCount == 0 {
+		return
+	}
+	entry.refCount--
+}
+
+// Unload destroys the cached renderable (and thus GPU resources) of every
+// loaded component in group, clears the group's index, and closes its
+// mounted sources (unmapping any mounted zip archives).
+func (m *Manager) Unload(group string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, okay := m.groups[group]
+	if !okay {
+		return
+	}
+
+	for name, entry := range g.index {
+		if entry.loaded != nil && entry.refCount > 0 {
+			groggy.Log("WARN", "component: unloading group %q while %q still has %d reference(s).", group, name, entry.refCount)
+		}
+		if entry.loaded != nil {
+			entry.loaded.Destroy()
+		}
+	}
+
+	for _, src := range g.sources {
+		src.Close()
+	}
+
+	delete(m.groups, group)
+}
@@ -0,0 +1,29 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LoadComponentFromJSON parses the JSON component definition in data and
+// returns the resulting Component. componentDirPath is the directory the
+// component file lives in (with a trailing path separator) and is stashed
+// on the Component so that BinFile/SrcFile/Textures can be resolved
+// relative to it.
+func LoadComponentFromJSON(data []byte, componentDirPath string) (*Component, error) {
+	c := new(Component)
+	err := json.Unmarshal(data, c)
+	if err != nil {
+		return nil, fmt.Errorf("component: failed to unmarshal component JSON: %v", err)
+	}
+
+	c.componentDirPath = componentDirPath
+	for _, mesh := range c.Meshes {
+		mesh.Parent = c
+	}
+
+	return c, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// hullFace is a single triangle of a convex hull under construction, with
+// indices into the original point slice and an outward-facing normal.
+type hullFace struct {
+	a, b, c int
+	normal  mgl.Vec3
+}
+
+// edges returns the face's three directed boundary edges, each ordered so
+// that walking a->b->c->a keeps the face's interior on the left (when
+// viewed from outside the hull, matching the outward normal).
+func (f hullFace) edges() [3][2]int {
+	return [3][2]int{{f.a, f.b}, {f.b, f.c}, {f.c, f.a}}
+}
+
+// quickHull computes the convex hull of points using the QuickHull
+// algorithm and returns the set of points that lie on the hull. Points
+// strictly inside the hull are dropped from the result.
+func quickHull(points []mgl.Vec3) ([]mgl.Vec3, error) {
+	if len(points) < 4 {
+		return points, nil
+	}
+
+	i0, i1, i2, i3, err := initialTetrahedron(points)
+	if err != nil {
+		// the point cloud is degenerate (coplanar/collinear); there's no
+		// well-formed 3D hull to build, so just return the input unchanged.
+		return points, nil
+	}
+
+	centroid := points[i0].Add(points[i1]).Add(points[i2]).Add(points[i3]).Mul(0.25)
+
+	faces := []hullFace{
+		newHullFace(points, i0, i1, i2, centroid),
+		newHullFace(points, i0, i3, i1, centroid),
+		newHullFace(points, i0, i2, i3, centroid),
+		newHullFace(points, i1, i3, i2, centroid),
+	}
+
+	for pi, p := range points {
+		if pi == i0 || pi == i1 || pi == i2 || pi == i3 {
+			continue
+		}
+
+		var visible []int
+		for fi, f := range faces {
+			if f.normal.Dot(p.Sub(points[f.a])) > 1e-5 {
+				visible = append(visible, fi)
+			}
+		}
+		if len(visible) == 0 {
+			continue
+		}
+
+		horizon := findHorizon(faces, visible)
+
+		visibleSet := make(map[int]bool, len(visible))
+		for _, fi := range visible {
+			visibleSet[fi] = true
+		}
+		remaining := faces[:0]
+		for fi, f := range faces {
+			if !visibleSet[fi] {
+				remaining = append(remaining, f)
+			}
+		}
+		faces = remaining
+
+		for _, e := range horizon {
+			faces = append(faces, newHullFace(points, e[0], e[1], pi, centroid))
+		}
+	}
+
+	used := make(map[int]bool)
+	for _, f := range faces {
+		used[f.a], used[f.b], used[f.c] = true, true, true
+	}
+
+	hullPoints := make([]mgl.Vec3, 0, len(used))
+	for idx := range used {
+		hullPoints = append(hullPoints, points[idx])
+	}
+
+	return hullPoints, nil
+}
+
+// newHullFace builds a hullFace for the triangle (a,b,c), flipping its
+// winding if necessary so that the resulting normal points away from
+// interior (a point known to be inside the hull, such as its centroid).
+func newHullFace(points []mgl.Vec3, a, b, c int, interior mgl.Vec3) hullFace {
+	normal := triangleNormal(points[a], points[b], points[c])
+	if normal.Dot(points[a].Sub(interior)) < 0 {
+		a, b = b, a
+		normal = triangleNormal(points[a], points[b], points[c])
+	}
+	return hullFace{a: a, b: b, c: c, normal: normal}
+}
+
+// triangleNormal returns the (unnormalized-safe) unit normal of the
+// triangle (a,b,c).
+func triangleNormal(a, b, c mgl.Vec3) mgl.Vec3 {
+	n := b.Sub(a).Cross(c.Sub(a))
+	if n.Len() < 1e-12 {
+		return n
+	}
+	return n.Normalize()
+}
+
+// findHorizon returns the directed boundary edges separating the visible
+// faces (by index into faces) from the rest of the hull: edges that belong
+// to exactly one visible face.
+func findHorizon(faces []hullFace, visible []int) [][2]int {
+	visibleEdges := make(map[[2]int]bool)
+	for _, fi := range visible {
+		for _, e := range faces[fi].edges() {
+			visibleEdges[e] = true
+		}
+	}
+
+	var horizon [][2]int
+	for _, fi := range visible {
+		for _, e := range faces[fi].edges() {
+			reverse := [2]int{e[1], e[0]}
+			if !visibleEdges[reverse] {
+				horizon = append(horizon, e)
+			}
+		}
+	}
+
+	return horizon
+}
+
+// initialTetrahedron picks four non-coplanar points from points to seed the
+// hull: the two points farthest apart along the X axis, the point farthest
+// from the line between them, and the point farthest from the plane formed
+// by the first three.
+func initialTetrahedron(points []mgl.Vec3) (i0, i1, i2, i3 int, err error) {
+	minX, maxX := 0, 0
+	for i, p := range points {
+		if p[0] < points[minX][0] {
+			minX = i
+		}
+		if p[0] > points[maxX][0] {
+			maxX = i
+		}
+	}
+	if minX == maxX {
+		return 0, 0, 0, 0, fmt.Errorf("component: degenerate point cloud (all points coincide on X)")
+	}
+	i0, i1 = minX, maxX
+
+	lineDir := points[i1].Sub(points[i0]).Normalize()
+	bestDist := float32(-1)
+	for i, p := range points {
+		toPoint := p.Sub(points[i0])
+		perp := toPoint.Sub(lineDir.Mul(toPoint.Dot(lineDir)))
+		d := perp.Len()
+		if d > bestDist {
+			bestDist = d
+			i2 = i
+		}
+	}
+	if bestDist < 1e-6 {
+		return 0, 0, 0, 0, fmt.Errorf("component: degenerate point cloud (all points collinear)")
+	}
+
+	planeNormal := triangleNormal(points[i0], points[i1], points[i2])
+	bestDist = -1
+	for i, p := range points {
+		d := planeNormal.Dot(p.Sub(points[i0]))
+		if d < 0 {
+			d = -d
+		}
+		if d > bestDist {
+			bestDist = d
+			i3 = i
+		}
+	}
+	if bestDist < 1e-6 {
+		return 0, 0, 0, 0, fmt.Errorf("component: degenerate point cloud (all points coplanar)")
+	}
+
+	return i0, i1, i2, i3, nil
+}
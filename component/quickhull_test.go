@@ -0,0 +1,84 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func TestQuickHullCube(t *testing.T) {
+	cube := []mgl.Vec3{
+		{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1},
+		{-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1},
+	}
+
+	hull, err := quickHull(cube)
+	if err != nil {
+		t.Fatalf("quickHull returned an error for a cube: %v", err)
+	}
+
+	// every corner of a cube lies on its own hull, so none should have been
+	// dropped as interior.
+	if len(hull) != len(cube) {
+		t.Fatalf("expected all %d cube corners on the hull, got %d", len(cube), len(hull))
+	}
+
+	seen := make(map[mgl.Vec3]bool, len(hull))
+	for _, p := range hull {
+		seen[p] = true
+	}
+	for _, p := range cube {
+		if !seen[p] {
+			t.Errorf("cube corner %v missing from hull result", p)
+		}
+	}
+}
+
+func TestQuickHullCubeWithInteriorPoint(t *testing.T) {
+	points := []mgl.Vec3{
+		{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1},
+		{-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1},
+		{0, 0, 0}, // dead center, strictly interior
+	}
+
+	hull, err := quickHull(points)
+	if err != nil {
+		t.Fatalf("quickHull returned an error: %v", err)
+	}
+
+	for _, p := range hull {
+		if p == (mgl.Vec3{0, 0, 0}) {
+			t.Fatalf("interior point %v should have been dropped from the hull", p)
+		}
+	}
+}
+
+func TestQuickHullDegenerateCoplanar(t *testing.T) {
+	// all points share z == 0; there's no well-formed 3D hull to build.
+	points := []mgl.Vec3{
+		{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {0.5, 0.5, 0},
+	}
+
+	hull, err := quickHull(points)
+	if err != nil {
+		t.Fatalf("quickHull returned an error for a degenerate point cloud: %v", err)
+	}
+	if len(hull) != len(points) {
+		t.Fatalf("expected degenerate input to pass through unchanged (%d points), got %d", len(points), len(hull))
+	}
+}
+
+func TestQuickHullTooFewPoints(t *testing.T) {
+	points := []mgl.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+
+	hull, err := quickHull(points)
+	if err != nil {
+		t.Fatalf("quickHull returned an error for fewer than 4 points: %v", err)
+	}
+	if len(hull) != len(points) {
+		t.Fatalf("expected input to pass through unchanged (%d points), got %d", len(points), len(hull))
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// joinRel joins a '/'-separated directory (possibly empty) and a relative
+// file name into a single '/'-separated relative path.
+func joinRel(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
+
+// openAsset opens relName -- a BinFile, texture, or SrcFile path relative to
+// the owning component -- routing the read through the resourceSource the
+// component was loaded from (so it works for both directory-mounted and
+// zip-mounted resource groups), or falling back to the local filesystem for
+// components loaded directly via LoadComponentFromJSON.
+func (cm *ComponentMesh) openAsset(relName string) (io.ReadCloser, error) {
+	parent := cm.Parent
+	if parent.vfsSource != nil {
+		return parent.vfsSource.Open(joinRel(parent.vfsDir, relName))
+	}
+	return os.Open(parent.componentDirPath + relName)
+}
+
+// resolveOSPath returns the real, on-disk path for relName, and whether one
+// exists. It exists for the minority of callers (namely SrcFile importers)
+// that need an actual filesystem path rather than a byte stream, and can't
+// be satisfied when the component was loaded from a source with no
+// filesystem root, such as a mounted zip archive.
+func (cm *ComponentMesh) resolveOSPath(relName string) (string, bool) {
+	parent := cm.Parent
+	if parent.vfsSource == nil {
+		return parent.componentDirPath + relName, true
+	}
+
+	root, okay := parent.vfsSource.RootDir()
+	if !okay {
+		return "", false
+	}
+
+	return filepath.Join(root, filepath.FromSlash(joinRel(parent.vfsDir, relName))), true
+}
+
+// resolvePath is like resolveOSPath but always returns a best-effort string,
+// falling back to the componentDirPath-relative convention when relName
+// can't be resolved to a real filesystem path (e.g. it lives in a zip).
+// It's meant for callers that just want a path to report or hand to code
+// outside this package (e.g. fizzle.TextureManager), not to open directly.
+func (cm *ComponentMesh) resolvePath(relName string) string {
+	if path, okay := cm.resolveOSPath(relName); okay {
+		return path
+	}
+	return cm.Parent.componentDirPath + relName
+}